@@ -0,0 +1,112 @@
+// Package idempotency защищает POST /payment от повторной выдачи токенов
+// при ретраях ненадёжных IoT-клиентов: ответ на запрос с тем же
+// Idempotency-Key возвращается повторно вместо повторного выполнения.
+package idempotency
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Entry — закэшированный ответ на один идемпотентный запрос.
+type Entry struct {
+	BodyHash   string
+	StatusCode int
+	Body       []byte
+	Header     http.Header
+	ExpiresAt  time.Time
+}
+
+// Store — хранилище идемпотентных ответов. Реализация в памяти приведена
+// ниже (ShardedCache); на её место можно подставить Redis-бэкенд, не
+// трогая middleware.
+type Store interface {
+	Get(key string) (Entry, bool)
+	Put(key string, entry Entry)
+}
+
+// shardCount — число шардов в ShardedCache. Шардирование снижает
+// конкуренцию за мьютекс под нагрузкой от множества устройств.
+const shardCount = 32
+
+type shard struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// ShardedCache — реализация Store в памяти процесса с TTL и шардированием
+// по ключу.
+type ShardedCache struct {
+	shards [shardCount]*shard
+	ttl    time.Duration
+}
+
+// NewShardedCache создаёт кэш, в котором каждая запись живёт ttl с момента
+// добавления.
+func NewShardedCache(ttl time.Duration) *ShardedCache {
+	c := &ShardedCache{ttl: ttl}
+	for i := range c.shards {
+		c.shards[i] = &shard{entries: make(map[string]Entry)}
+	}
+	return c
+}
+
+func (c *ShardedCache) shardFor(key string) *shard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%shardCount]
+}
+
+// Get возвращает запись по ключу, если она есть и ещё не истекла.
+func (c *ShardedCache) Get(key string) (Entry, bool) {
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return Entry{}, false
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		delete(s.entries, key)
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Put сохраняет запись под ключом key, проставляя TTL кэша.
+func (c *ShardedCache) Put(key string, entry Entry) {
+	entry.ExpiresAt = time.Now().Add(c.ttl)
+
+	s := c.shardFor(key)
+	s.mu.Lock()
+	s.entries[key] = entry
+	s.mu.Unlock()
+}
+
+// StartSweeper запускает фоновую горутину, которая каждые interval вычищает
+// истёкшие записи из всех шардов, чтобы память не росла неограниченно.
+func (c *ShardedCache) StartSweeper(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			c.sweep()
+		}
+	}()
+}
+
+func (c *ShardedCache) sweep() {
+	now := time.Now()
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key, entry := range s.entries {
+			if now.After(entry.ExpiresAt) {
+				delete(s.entries, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
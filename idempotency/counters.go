@@ -0,0 +1,22 @@
+package idempotency
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Counters — счётчики в стиле Prometheus, отражающие работу middleware.
+type Counters struct {
+	hits      uint64
+	conflicts uint64
+}
+
+func (c *Counters) incHit()      { atomic.AddUint64(&c.hits, 1) }
+func (c *Counters) incConflict() { atomic.AddUint64(&c.conflicts, 1) }
+
+// WriteProm пишет счётчики в текстовом формате экспозиции Prometheus.
+func (c *Counters) WriteProm(w io.Writer) {
+	fmt.Fprintf(w, "# TYPE idempotent_hits counter\nidempotent_hits %d\n", atomic.LoadUint64(&c.hits))
+	fmt.Fprintf(w, "# TYPE idempotent_conflicts counter\nidempotent_conflicts %d\n", atomic.LoadUint64(&c.conflicts))
+}
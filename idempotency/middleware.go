@@ -0,0 +1,142 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// HeaderName — HTTP-заголовок, которым клиент помечает логически одну и ту
+// же попытку оплаты.
+const HeaderName = "Idempotency-Key"
+
+// inflightCall отмечает, что запрос с данным ключом уже выполняется: пока
+// один запрос ещё не завершился, одновременные ретраи с тем же ключом
+// ждут его результата вместо того, чтобы параллельно выполнить handler и
+// выпустить два токена на одну и ту же покупку.
+type inflightCall struct {
+	done chan struct{}
+}
+
+// Middleware оборачивает handler поддержкой Idempotency-Key: повторный
+// запрос с тем же ключом и телом получает закэшированный ответ с
+// заголовком Idempotent-Replay, а тот же ключ с другим телом — 409.
+// Параллельные запросы с одним и тем же ключом сериализуются: выполняется
+// только первый, остальные дожидаются его результата. Если первый ответ
+// неуспешен (не 2xx) и потому не закэширован, следующий из ожидающих
+// выполняет handler сам.
+func Middleware(store Store, counters *Counters, next http.HandlerFunc) http.HandlerFunc {
+	var inflight sync.Map // ключ -> *inflightCall
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderName)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "не удалось прочитать тело запроса", http.StatusBadRequest)
+			return
+		}
+		r.Body.Close()
+
+		bodyHash := hashBody(key, body)
+
+		for {
+			if entry, ok := store.Get(key); ok {
+				if entry.BodyHash != bodyHash {
+					counters.incConflict()
+					http.Error(w, "Idempotency-Key уже использован с другим телом запроса", http.StatusConflict)
+					return
+				}
+
+				counters.incHit()
+				for name, values := range entry.Header {
+					for _, v := range values {
+						w.Header().Add(name, v)
+					}
+				}
+				w.Header().Set("Idempotent-Replay", "true")
+				w.WriteHeader(entry.StatusCode)
+				w.Write(entry.Body)
+				return
+			}
+
+			call := &inflightCall{done: make(chan struct{})}
+			actual, loaded := inflight.LoadOrStore(key, call)
+			if loaded {
+				// Другой запрос с тем же ключом уже выполняется — ждём его
+				// завершения и заново проверяем кэш.
+				<-actual.(*inflightCall).done
+				continue
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			rec := &responseRecorder{ResponseWriter: w, header: make(http.Header), statusCode: http.StatusOK}
+			next(rec, r)
+
+			// Кэшируем только успешные ответы. Транзиентные отказы (сервис
+			// ещё не зарегистрирован, внутренняя ошибка и т. п.) не должны
+			// залипать на весь TTL — иначе ретраи того же флаки-клиента с
+			// тем же ключом будут получать закэшированную неудачу вместо
+			// новой попытки.
+			if rec.statusCode >= 200 && rec.statusCode < 300 {
+				store.Put(key, Entry{
+					BodyHash:   bodyHash,
+					StatusCode: rec.statusCode,
+					Body:       rec.body.Bytes(),
+					Header:     rec.header,
+				})
+			}
+
+			inflight.Delete(key)
+			close(call.done)
+			return
+		}
+	}
+}
+
+func hashBody(key string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(key))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder перехватывает ответ next, чтобы сохранить его в Store,
+// одновременно пропуская его клиенту как обычно.
+type responseRecorder struct {
+	http.ResponseWriter
+	header     http.Header
+	body       bytes.Buffer
+	statusCode int
+	wroteHead  bool
+}
+
+func (rr *responseRecorder) Header() http.Header {
+	return rr.header
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.statusCode = status
+	rr.wroteHead = true
+	for name, values := range rr.header {
+		for _, v := range values {
+			rr.ResponseWriter.Header().Add(name, v)
+		}
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if !rr.wroteHead {
+		rr.WriteHeader(http.StatusOK)
+	}
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareReplaysSameKeyAndBody(t *testing.T) {
+	cache := NewShardedCache(time.Minute)
+	counters := &Counters{}
+
+	var calls int
+	handler := Middleware(cache, counters, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"token":"tok_1"}`))
+	})
+
+	mkReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/payment", strings.NewReader(`{"service_id":"s"}`))
+		req.Header.Set(HeaderName, "key-1")
+		return req
+	}
+
+	rec1 := httptest.NewRecorder()
+	handler(rec1, mkReq())
+	if calls != 1 {
+		t.Fatalf("ожидался 1 вызов обработчика, получено %d", calls)
+	}
+	if rec1.Header().Get("Idempotent-Replay") != "" {
+		t.Fatal("первый запрос не должен быть помечен как replay")
+	}
+
+	rec2 := httptest.NewRecorder()
+	handler(rec2, mkReq())
+	if calls != 1 {
+		t.Fatalf("обработчик не должен был вызываться повторно, вызовов: %d", calls)
+	}
+	if rec2.Header().Get("Idempotent-Replay") != "true" {
+		t.Fatal("повторный запрос должен быть помечен Idempotent-Replay: true")
+	}
+	if rec2.Body.String() != `{"token":"tok_1"}` {
+		t.Fatalf("повторный запрос должен вернуть закэшированное тело, получено %q", rec2.Body.String())
+	}
+}
+
+func TestMiddlewareConflictsOnDifferentBody(t *testing.T) {
+	cache := NewShardedCache(time.Minute)
+	counters := &Counters{}
+
+	handler := Middleware(cache, counters, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req1 := httptest.NewRequest(http.MethodPost, "/payment", strings.NewReader(`{"a":1}`))
+	req1.Header.Set(HeaderName, "key-1")
+	handler(httptest.NewRecorder(), req1)
+
+	req2 := httptest.NewRequest(http.MethodPost, "/payment", strings.NewReader(`{"a":2}`))
+	req2.Header.Set(HeaderName, "key-1")
+	rec2 := httptest.NewRecorder()
+	handler(rec2, req2)
+
+	if rec2.Code != http.StatusConflict {
+		t.Fatalf("ожидался 409 при совпадении ключа с другим телом, получено %d", rec2.Code)
+	}
+}
+
+func TestMiddlewareDoesNotCacheTransientFailures(t *testing.T) {
+	cache := NewShardedCache(time.Minute)
+	counters := &Counters{}
+
+	var calls int
+	handler := Middleware(cache, counters, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "сервис недоступен", http.StatusNotFound)
+	})
+
+	mkReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/payment", strings.NewReader(`{"a":1}`))
+		req.Header.Set(HeaderName, "key-1")
+		return req
+	}
+
+	handler(httptest.NewRecorder(), mkReq())
+	handler(httptest.NewRecorder(), mkReq())
+
+	if calls != 2 {
+		t.Fatalf("неуспешный ответ не должен кэшироваться — ожидалось 2 вызова, получено %d", calls)
+	}
+}
+
+// TestMiddlewareSerializesConcurrentRetries воспроизводит ретрай от
+// нестабильного IoT-клиента, который повторяет запрос, не дождавшись
+// ответа: два одновременных запроса с одним и тем же Idempotency-Key и
+// телом должны привести ровно к одному выполнению обработчика, а не к
+// выпуску двух токенов на одну и ту же покупку.
+func TestMiddlewareSerializesConcurrentRetries(t *testing.T) {
+	cache := NewShardedCache(time.Minute)
+	counters := &Counters{}
+
+	var calls int32
+	handler := Middleware(cache, counters, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"token":"tok_1"}`))
+	})
+
+	mkReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/payment", strings.NewReader(`{"service_id":"s"}`))
+		req.Header.Set(HeaderName, "key-1")
+		return req
+	}
+
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, 2)
+	for i := range recs {
+		i := i
+		recs[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler(recs[i], mkReq())
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("ожидалось ровно 1 выполнение обработчика на конкурентные ретраи с тем же ключом, получено %d", got)
+	}
+	for _, rec := range recs {
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"token":"tok_1"}` {
+			t.Fatalf("оба конкурентных запроса должны получить один и тот же успешный ответ, получено code=%d body=%q", rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package logclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ringCapacity — ёмкость кольцевого буфера событий, ожидающих отправки.
+const ringCapacity = 4096
+
+// Client буферизует события платёжного сервиса и отправляет их на
+// log-endpoint пакетами. Если endpoint недоступен, события дописываются в
+// fallbackPath, чтобы не потерять их безвозвратно.
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+	ring       *ringBuffer
+	fallback   *fileFallback
+}
+
+// New создаёт клиента централизованного логирования. endpoint — URL
+// лог-сервера, на который POST'ятся пакеты событий; fallbackPath — файл,
+// в который события дописываются, пока endpoint недоступен.
+func New(endpoint, fallbackPath string) *Client {
+	return &Client{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+		ring:       newRingBuffer(ringCapacity),
+		fallback:   newFileFallback(fallbackPath),
+	}
+}
+
+// Log ставит событие в очередь на отправку. Вызов никогда не блокируется
+// на сетевом I/O — платёжный путь от него не зависит.
+func (c *Client) Log(evt Event) {
+	c.ring.Push(evt)
+}
+
+// StartFlusher запускает фоновую горутину, которая каждые interval
+// выгружает накопленные события и пытается отправить их на log-endpoint;
+// при неудаче события уходят в файл-фолбэк.
+func (c *Client) StartFlusher(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			c.flush()
+		}
+	}()
+}
+
+func (c *Client) flush() {
+	events := c.ring.Drain()
+	if len(events) == 0 {
+		return
+	}
+
+	if c.endpoint == "" || c.send(events) != nil {
+		c.fallback.Write(events)
+	}
+}
+
+func (c *Client) send(events []Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Post(c.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return errStatus(resp.StatusCode)
+	}
+	return nil
+}
+
+type errStatus int
+
+func (e errStatus) Error() string {
+	return "log-server вернул неуспешный статус"
+}
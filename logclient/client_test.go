@@ -0,0 +1,84 @@
+package logclient
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFlushFallsBackOnSendFailure проверяет, что при недоступном
+// log-endpoint накопленные события уходят в файл-фолбэк, а не теряются.
+func TestFlushFallsBackOnSendFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	fallbackPath := filepath.Join(t.TempDir(), "events.log")
+	client := New(server.URL, fallbackPath)
+	client.Log(Event{Service: "billing", Method: "refund"})
+	client.Log(Event{Service: "billing", Method: "issue"})
+
+	client.flush()
+
+	f, err := os.Open(fallbackPath)
+	if err != nil {
+		t.Fatalf("ожидался файл-фолбэк с событиями, open вернул ошибку: %v", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var evt Event
+		if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+			t.Fatalf("не удалось разобрать строку файла-фолбэка: %v", err)
+		}
+		events = append(events, evt)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("ожидалось 2 события в файле-фолбэке, получено %d", len(events))
+	}
+	if events[0].Method != "refund" || events[1].Method != "issue" {
+		t.Fatalf("события в файле-фолбэке не совпадают с отправленными: %+v", events)
+	}
+}
+
+// TestFlushSendsToReachableEndpoint проверяет, что при доступном
+// log-endpoint события уходят туда и файл-фолбэк не используется.
+func TestFlushSendsToReachableEndpoint(t *testing.T) {
+	received := make(chan []Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var events []Event
+		if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+			t.Errorf("сервер не смог разобрать тело запроса: %v", err)
+		}
+		received <- events
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fallbackPath := filepath.Join(t.TempDir(), "events.log")
+	client := New(server.URL, fallbackPath)
+	client.Log(Event{Service: "billing", Method: "refund"})
+
+	client.flush()
+
+	select {
+	case events := <-received:
+		if len(events) != 1 || events[0].Method != "refund" {
+			t.Fatalf("сервер получил неожиданные события: %+v", events)
+		}
+	default:
+		t.Fatal("сервер не получил событие")
+	}
+
+	if _, err := os.Stat(fallbackPath); !os.IsNotExist(err) {
+		t.Fatal("файл-фолбэк не должен создаваться, если log-endpoint доступен")
+	}
+}
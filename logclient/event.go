@@ -0,0 +1,26 @@
+// Package logclient отправляет структурированные события платёжного
+// сервиса в централизованный лог-сервис, с буферизацией на случай его
+// недоступности — по образцу отдельного лог-микросервиса из учебных
+// примеров распределённых систем на Go.
+package logclient
+
+import "time"
+
+// Уровни событий.
+const (
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Event — одно событие платёжного сервиса, отправляемое лог-серверу.
+type Event struct {
+	Ts        time.Time `json:"ts"`
+	Level     string    `json:"level"`
+	Service   string    `json:"service,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	TokenJti  string    `json:"token_jti,omitempty"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	Err       string    `json:"err,omitempty"`
+}
@@ -0,0 +1,51 @@
+package logclient
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// maxFallbackFileSize — порог размера файла, после которого он
+// ротируется в path+".1", прежде чем в него допишут новые события.
+const maxFallbackFileSize = 10 * 1024 * 1024 // 10 МБ
+
+// fileFallback пишет события построчно в JSON-файл, когда лог-сервер
+// недоступен, с простой ротацией по размеру.
+type fileFallback struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newFileFallback(path string) *fileFallback {
+	return &fileFallback{path: path}
+}
+
+// Write дописывает события в файл, предварительно ротируя его при
+// необходимости.
+func (f *fileFallback) Write(events []Event) error {
+	if f.path == "" || len(events) == 0 {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if info, err := os.Stat(f.path); err == nil && info.Size() > maxFallbackFileSize {
+		os.Rename(f.path, f.path+".1")
+	}
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	for _, evt := range events {
+		if err := enc.Encode(evt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
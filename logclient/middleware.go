@@ -0,0 +1,118 @@
+package logclient
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type contextKey struct{}
+
+var eventContextKey = contextKey{}
+
+// RequestEvent накапливает детали одного запроса (сервис, метод, jti
+// выпущенного токена, ошибку) по мере того, как их узнаёт обработчик.
+// Middleware забирает накопленное и отправляет как единое событие вместе
+// с латентностью и уровнем, выведенным из статуса ответа.
+type RequestEvent struct {
+	mu       sync.Mutex
+	Service  string
+	Method   string
+	TokenJti string
+	Err      string
+}
+
+// SetService задаёт сервис и метод, к которым относится запрос.
+func (e *RequestEvent) SetService(service, method string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Service, e.Method = service, method
+}
+
+// SetTokenJti задаёт идентификатор выпущенного токена.
+func (e *RequestEvent) SetTokenJti(jti string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.TokenJti = jti
+}
+
+// SetErr задаёт причину отказа (провалена валидация, сервис недоступен и т. д.).
+func (e *RequestEvent) SetErr(err string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Err = err
+}
+
+// EventFromContext возвращает RequestEvent текущего запроса, если он
+// обрабатывается под Middleware.
+func EventFromContext(ctx context.Context) *RequestEvent {
+	evt, _ := ctx.Value(eventContextKey).(*RequestEvent)
+	return evt
+}
+
+// Middleware оборачивает next, измеряя время обработки и статус ответа и
+// отправляя итоговое событие в Client. Значения service/method/token_jti/err
+// обработчик прокидывает через RequestEvent из контекста запроса.
+func Middleware(client *Client, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		evt := &RequestEvent{}
+		ctx := context.WithValue(r.Context(), eventContextKey, evt)
+
+		rec := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next(rec, r.WithContext(ctx))
+
+		level := LevelInfo
+		switch {
+		case rec.statusCode >= 500:
+			level = LevelError
+		case rec.statusCode >= 400:
+			level = LevelWarn
+		}
+
+		client.Log(Event{
+			Ts:        time.Now(),
+			Level:     level,
+			Service:   evt.Service,
+			Method:    evt.Method,
+			TokenJti:  evt.TokenJti,
+			ClientIP:  clientIP(r),
+			LatencyMs: time.Since(start).Milliseconds(),
+			Err:       evt.Err,
+		})
+	}
+}
+
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		return strings.TrimSpace(strings.Split(fwd, ",")[0])
+	}
+	host := r.RemoteAddr
+	if idx := strings.LastIndex(host, ":"); idx != -1 {
+		return host[:idx]
+	}
+	return host
+}
+
+// statusRecorder перехватывает код статуса ответа для логирования, не
+// вмешиваясь в остальную обработку.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	wroteHead  bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.statusCode = status
+	rec.wroteHead = true
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHead {
+		rec.WriteHeader(http.StatusOK)
+	}
+	return rec.ResponseWriter.Write(b)
+}
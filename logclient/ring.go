@@ -0,0 +1,55 @@
+package logclient
+
+import "sync"
+
+// ringBuffer — кольцевой буфер ограниченной ёмкости: при переполнении
+// самое старое событие отбрасывается, чтобы медленный лог-сервер никогда
+// не блокировал платёжный путь.
+type ringBuffer struct {
+	mu       sync.Mutex
+	events   []Event
+	capacity int
+	head     int // индекс самого старого события
+	size     int
+}
+
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{
+		events:   make([]Event, capacity),
+		capacity: capacity,
+	}
+}
+
+// Push добавляет событие, затирая самое старое, если буфер уже полон.
+func (rb *ringBuffer) Push(evt Event) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	tail := (rb.head + rb.size) % rb.capacity
+	rb.events[tail] = evt
+
+	if rb.size < rb.capacity {
+		rb.size++
+	} else {
+		// Буфер был полон — самое старое событие только что затёрто.
+		rb.head = (rb.head + 1) % rb.capacity
+	}
+}
+
+// Drain извлекает все накопленные события и очищает буфер.
+func (rb *ringBuffer) Drain() []Event {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if rb.size == 0 {
+		return nil
+	}
+
+	drained := make([]Event, rb.size)
+	for i := 0; i < rb.size; i++ {
+		drained[i] = rb.events[(rb.head+i)%rb.capacity]
+	}
+	rb.head = 0
+	rb.size = 0
+	return drained
+}
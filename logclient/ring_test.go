@@ -0,0 +1,61 @@
+package logclient
+
+import "testing"
+
+func TestRingBufferDrainsInOrder(t *testing.T) {
+	rb := newRingBuffer(4)
+	rb.Push(Event{Service: "a"})
+	rb.Push(Event{Service: "b"})
+	rb.Push(Event{Service: "c"})
+
+	drained := rb.Drain()
+	if len(drained) != 3 {
+		t.Fatalf("ожидалось 3 события, получено %d", len(drained))
+	}
+	for i, want := range []string{"a", "b", "c"} {
+		if drained[i].Service != want {
+			t.Fatalf("drained[%d].Service = %q, ожидалось %q", i, drained[i].Service, want)
+		}
+	}
+
+	if got := rb.Drain(); got != nil {
+		t.Fatalf("повторный Drain после опустошения должен вернуть nil, получено %v", got)
+	}
+}
+
+// TestRingBufferOverflowDropsOldest проверяет wraparound-арифметику
+// head/size: когда событий больше, чем capacity, затираются самые старые,
+// а Drain отдаёт оставшиеся в правильном порядке.
+func TestRingBufferOverflowDropsOldest(t *testing.T) {
+	rb := newRingBuffer(3)
+	for i := 0; i < 5; i++ {
+		rb.Push(Event{Service: string(rune('a' + i))}) // a, b, c, d, e
+	}
+
+	drained := rb.Drain()
+	if len(drained) != 3 {
+		t.Fatalf("ожидалось 3 события после переполнения буфера ёмкостью 3, получено %d", len(drained))
+	}
+	for i, want := range []string{"c", "d", "e"} {
+		if drained[i].Service != want {
+			t.Fatalf("drained[%d].Service = %q, ожидалось %q (самые старые события должны быть отброшены)", i, drained[i].Service, want)
+		}
+	}
+}
+
+// TestRingBufferOverflowThenRefillAfterDrain проверяет, что буфер остаётся
+// пригодным для использования после переполнения и опустошения — head и
+// size корректно сбрасываются, а не застревают на смещённом индексе.
+func TestRingBufferOverflowThenRefillAfterDrain(t *testing.T) {
+	rb := newRingBuffer(2)
+	rb.Push(Event{Service: "a"})
+	rb.Push(Event{Service: "b"})
+	rb.Push(Event{Service: "c"}) // затирает "a"
+	rb.Drain()
+
+	rb.Push(Event{Service: "x"})
+	drained := rb.Drain()
+	if len(drained) != 1 || drained[0].Service != "x" {
+		t.Fatalf("ожидалось одно событие %q после повторного заполнения, получено %v", "x", drained)
+	}
+}
@@ -1,116 +1,220 @@
 package main
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	crand "crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
-	"sync"
+	"os"
 	"time"
+
+	"iot_lab3/idempotency"
+	"iot_lab3/logclient"
+	"iot_lab3/payment"
+	"iot_lab3/registry"
+	"iot_lab3/token"
 )
 
-// PaymentRequest представляет запрос на обработку оплаты.
-type PaymentRequest struct {
-	ServiceID string    `json:"service_id"` // Идентификатор сервиса
-	Method    string    `json:"method"`     // Метод сервиса
-	From      time.Time `json:"from"`       // Начало периода оплаты
-	To        time.Time `json:"to"`         // Конец периода оплаты
+// Создаем глобальный реестр сервисов. Сервисы регистрируются сами через
+// POST /services вместо того, чтобы быть захардкоженными здесь.
+var svcRegistry = registry.New()
+
+// Интервал, с которым реестр опрашивает heartbeat-адреса зарегистрированных
+// экземпляров.
+const heartbeatInterval = 10 * time.Second
+
+// maxPaymentPeriod — максимальная длительность периода оплаты по умолчанию,
+// если сервис не заявил свою при регистрации в реестре.
+const maxPaymentPeriod = 30 * 24 * time.Hour
+
+// tokenIssuer подписывает и проверяет JWT-токены оплаты. Алгоритм и ключ
+// подписи задаются переменными окружения TOKEN_SIGNING_ALG (по умолчанию
+// HS256) и TOKEN_SIGNING_KEY, загружаемыми при старте. Хранилище отозванных
+// токенов — в памяти по умолчанию, либо файловое, если задан
+// TOKEN_REVOCATION_FILE (переживает перезапуск процесса).
+var tokenIssuer = newTokenIssuer()
+
+func newTokenIssuer() *token.Issuer {
+	alg := os.Getenv("TOKEN_SIGNING_ALG")
+	if alg == "" {
+		alg = "HS256"
+	}
+	key := []byte(os.Getenv("TOKEN_SIGNING_KEY"))
+	if len(key) == 0 {
+		key = []byte("insecure-dev-signing-key")
+	}
+
+	signer, err := token.NewSigner(alg, key)
+	if err != nil {
+		log.Fatalf("не удалось создать подписчика токенов: %v", err)
+	}
+	return token.NewIssuer(signer, newRevocationStore())
 }
 
-// PaymentResponse представляет ответ после обработки оплаты.
-type PaymentResponse struct {
-	Token  string    `json:"token"`  // Уникальный токен оплаты
-	From   time.Time `json:"from"`   // Начало периода оплаты
-	To     time.Time `json:"to"`     // Конец периода оплаты
-	Method string    `json:"method"` // Метод сервиса
+func newRevocationStore() token.RevocationStore {
+	path := os.Getenv("TOKEN_REVOCATION_FILE")
+	if path == "" {
+		return token.NewMemoryRevocationStore()
+	}
+
+	store, err := token.NewFileRevocationStore(path)
+	if err != nil {
+		log.Fatalf("не удалось открыть файл отозванных токенов %s: %v", path, err)
+	}
+	return store
 }
 
-// ServiceRegistry хранит доступные сервисы и их методы.
-type ServiceRegistry struct {
-	services map[string]map[string]bool // Карта сервисов и их методов
-	sync.RWMutex                        // Мьютекс для безопасного доступа к данным
+// commandRegistry хранит валидаторы и исполнителей команд оплаты по парам
+// (service_id, method). main связывает в неё команды, сам HTTP-слой об их
+// внутреннем устройстве не знает.
+var commandRegistry = payment.NewCommandRegistry(payment.Command{
+	Validators: []payment.PaymentValidator{
+		payment.PeriodValidator{Lookup: svcRegistry, Default: maxPaymentPeriod},
+	},
+	Executer: payment.TokenExecuter{Issuer: tokenIssuer},
+})
+
+func init() {
+	// "billing"/"refund" — пример команды, отличной от команды по
+	// умолчанию: другой валидатор (период должен уже закончиться) и другой
+	// исполнитель (возврат средств вместо выпуска токена).
+	commandRegistry.Register("billing", "refund", payment.Command{
+		Validators: []payment.PaymentValidator{
+			payment.RefundWindowValidator{},
+		},
+		Executer: payment.RefundExecuter{GenerateRefundID: generateRefundID},
+	})
 }
 
-// Создаем глобальный экземпляр регистра сервисов.
-var registry = &ServiceRegistry{
-	services: make(map[string]map[string]bool),
+// generateRefundID генерирует идентификатор возврата средств.
+func generateRefundID() string {
+	b := make([]byte, 16)
+	crand.Read(b)
+	return "rfnd_" + hex.EncodeToString(b)
 }
 
-// RegisterService регистрирует сервис и его метод в регистре.
-func (r *ServiceRegistry) RegisterService(serviceID, method string) {
-	r.Lock() // Блокируем запись в регистр
-	defer r.Unlock()
+// idempotencyTTL — на сколько запоминается ответ на запрос с
+// Idempotency-Key, чтобы ретраи от нестабильных IoT-клиентов не выпускали
+// токен повторно.
+const idempotencyTTL = 10 * time.Minute
 
-	// Если сервис еще не зарегистрирован, создаем для него пустую карту методов.
-	if _, exists := r.services[serviceID]; !exists {
-		r.services[serviceID] = make(map[string]bool)
-	}
+// idempotencySweepInterval — как часто фоновый sweeper вычищает истёкшие
+// записи из кэша идемпотентности.
+const idempotencySweepInterval = time.Minute
 
-	// Регистрируем метод для сервиса.
-	r.services[serviceID][method] = true
-}
+var (
+	idempotencyCache    = idempotency.NewShardedCache(idempotencyTTL)
+	idempotencyCounters = &idempotency.Counters{}
+)
 
-// IsServiceAvailable проверяет, доступен ли запрашиваемый сервис и метод.
-func (r *ServiceRegistry) IsServiceAvailable(serviceID, method string) bool {
-	r.RLock() // Блокируем чтение из регистра
-	defer r.RUnlock()
+// logFlushInterval — как часто фоновый флашер logclient выгружает
+// накопленные события на лог-сервер.
+const logFlushInterval = 2 * time.Second
 
-	// Проверяем, существует ли сервис и его метод.
-	if methods, exists := r.services[serviceID]; exists {
-		return methods[method]
-	}
-	return false
-}
+// logFallbackFile — куда дописываются события оплаты, если лог-сервер
+// недоступен.
+const logFallbackFile = "payment-events.log"
 
-// generateToken генерирует уникальный токен для оплаты.
-func generateToken() string {
-	b := make([]byte, 32)
-	rand.Read(b) // Генерируем случайные байты
-	return base64.URLEncoding.EncodeToString(b) // Кодируем в Base64
-}
+// logClient отправляет события платёжного сервиса на центральный
+// лог-сервер. Адрес задаётся флагом --log-endpoint или переменной
+// окружения LOG_ENDPOINT, считываемыми при старте в main.
+var logClient *logclient.Client
 
 // handlePayment обрабатывает запрос на оплату.
 func handlePayment(w http.ResponseWriter, r *http.Request) {
-	// Декодируем тело запроса в структуру PaymentRequest.
-	var req PaymentRequest
+	evt := logclient.EventFromContext(r.Context())
+
+	// Декодируем тело запроса в структуру payment.Request.
+	var req payment.Request
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Некорректный запрос", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close() // Закрываем тело запроса после обработки
 
+	if evt != nil {
+		evt.SetService(req.ServiceID, req.Method)
+	}
+
 	// Проверяем, доступен ли запрашиваемый сервис и метод.
-	if !registry.IsServiceAvailable(req.ServiceID, req.Method) {
+	if !svcRegistry.IsServiceAvailable(req.ServiceID, req.Method) {
+		if evt != nil {
+			evt.SetErr("сервис или метод недоступны")
+		}
 		http.Error(w, "Сервис или метод не найден", http.StatusNotFound)
 		return
 	}
 
-	// Генерируем уникальный токен для оплаты.
-	token := generateToken()
+	// Прогоняем запрос через команду оплаты: валидаторы, затем исполнитель.
+	resp, validationErr, err := commandRegistry.Dispatch(req)
+	if err != nil {
+		if evt != nil {
+			evt.SetErr(err.Error())
+		}
+		http.Error(w, "Не удалось обработать оплату", http.StatusInternalServerError)
+		return
+	}
+	if validationErr != nil {
+		if evt != nil {
+			evt.SetErr("ошибка валидации")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(validationErr)
+		return
+	}
 
-	// Формируем ответ с токеном и данными об оплате.
-	response := PaymentResponse{
-		Token:  token,
-		From:   req.From,
-		To:     req.To,
-		Method: req.Method,
+	if evt != nil {
+		evt.SetTokenJti(tokenIssuer.Introspect(resp.Token).Claims.Jti)
 	}
 
 	// Устанавливаем заголовок Content-Type и отправляем ответ в формате JSON.
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(resp)
 }
 
 func main() {
-	// Регистрируем несколько сервисов и методов для демонстрации.
-	registry.RegisterService("service1", "method1")
-	registry.RegisterService("service1", "method2")
-	registry.RegisterService("service2", "method1")
-
-	// Регистрируем обработчик для маршрута /payment.
-	http.HandleFunc("/payment", handlePayment)
+	logEndpoint := flag.String("log-endpoint", os.Getenv("LOG_ENDPOINT"), "URL лог-сервера, на который отправляются события оплаты")
+	flag.Parse()
+
+	logClient = logclient.New(*logEndpoint, logFallbackFile)
+	logClient.StartFlusher(logFlushInterval)
+
+	// Запускаем фоновую проверку heartbeat у зарегистрированных сервисов.
+	svcRegistry.StartHeartbeatChecker(heartbeatInterval)
+
+	// Запускаем фоновую чистку истёкших записей кэша идемпотентности.
+	idempotencyCache.StartSweeper(idempotencySweepInterval)
+
+	// Регистрируем обработчики саморегистрации и обнаружения сервисов.
+	http.HandleFunc("/services", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			svcRegistry.RegisterHandler(w, r)
+		case http.MethodGet:
+			svcRegistry.DiscoverHandler(w, r)
+		default:
+			http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/services/", svcRegistry.DeregisterHandler)
+
+	// Регистрируем обработчик для маршрута /payment, обёрнутый поддержкой
+	// Idempotency-Key и централизованным логированием.
+	paymentHandler := idempotency.Middleware(idempotencyCache, idempotencyCounters, handlePayment)
+	http.HandleFunc("/payment", logclient.Middleware(logClient, paymentHandler))
+
+	// Регистрируем интроспекцию и отзыв токенов оплаты.
+	http.HandleFunc("/payment/introspect", tokenIssuer.IntrospectHandler)
+	http.HandleFunc("/payment/revoke", tokenIssuer.RevokeHandler)
+
+	// Отдаём счётчики идемпотентности в формате Prometheus.
+	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		idempotencyCounters.WriteProm(w)
+	})
 
 	// Запускаем HTTP-сервер на порту 8080.
 	fmt.Println("Запуск сервиса оплаты на :8080")
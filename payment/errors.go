@@ -0,0 +1,14 @@
+package payment
+
+// FieldError описывает одну ошибку валидации, привязанную к конкретному полю.
+type FieldError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field"`
+}
+
+// ErrorResponse — структурированный ответ об ошибке валидации, который
+// возвращается клиенту вместо обычного текста.
+type ErrorResponse struct {
+	Errors []FieldError `json:"errors"`
+}
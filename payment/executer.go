@@ -0,0 +1,48 @@
+package payment
+
+import "time"
+
+// PaymentExecuter выполняет уже провалидированный запрос на оплату и
+// формирует ответ (выпуск токена, создание счёта, возврат средств и т. д.).
+type PaymentExecuter interface {
+	Execute(req Request) (Response, error)
+}
+
+// TokenIssuer выпускает подписанный токен оплаты на заданный период.
+type TokenIssuer interface {
+	Issue(serviceID, method string, from, to time.Time) (string, error)
+}
+
+// TokenExecuter — исполнитель по умолчанию: выпускает подписанный токен
+// оплаты через TokenIssuer (обычно — *token.Issuer).
+type TokenExecuter struct {
+	Issuer TokenIssuer
+}
+
+func (e TokenExecuter) Execute(req Request) (Response, error) {
+	tok, err := e.Issuer.Issue(req.ServiceID, req.Method, req.From, req.To)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{
+		Token:  tok,
+		From:   req.From,
+		To:     req.To,
+		Method: req.Method,
+	}, nil
+}
+
+// RefundExecuter — исполнитель для метода "refund": вместо выпуска JWT он
+// создаёт идентификатор возврата средств через переданную функцию.
+type RefundExecuter struct {
+	GenerateRefundID func() string
+}
+
+func (e RefundExecuter) Execute(req Request) (Response, error) {
+	return Response{
+		Token:  e.GenerateRefundID(),
+		From:   req.From,
+		To:     req.To,
+		Method: req.Method,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package payment
+
+import "sync"
+
+// CommandKey идентифицирует команду оплаты по сервису и методу.
+type CommandKey struct {
+	ServiceID string
+	Method    string
+}
+
+// Command — цепочка валидаторов и исполнитель, зарегистрированные для
+// конкретной пары (service_id, method).
+type Command struct {
+	Validators []PaymentValidator
+	Executer   PaymentExecuter
+}
+
+// CommandRegistry хранит команды оплаты, зарегистрированные для каждой
+// пары (service_id, method).
+type CommandRegistry struct {
+	mu       sync.RWMutex
+	commands map[CommandKey]Command
+	fallback Command
+}
+
+// NewCommandRegistry создаёт реестр команд с командой по умолчанию,
+// которая используется, если для пары (service_id, method) ничего не
+// зарегистрировано явно.
+func NewCommandRegistry(fallback Command) *CommandRegistry {
+	return &CommandRegistry{
+		commands: make(map[CommandKey]Command),
+		fallback: fallback,
+	}
+}
+
+// Register привязывает валидаторы и исполнителя к паре (service_id, method).
+func (cr *CommandRegistry) Register(serviceID, method string, cmd Command) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.commands[CommandKey{ServiceID: serviceID, Method: method}] = cmd
+}
+
+// Dispatch валидирует запрос и выполняет команду, зарегистрированную для
+// его (service_id, method), либо команду по умолчанию. При ошибках
+// валидации возвращается ErrorResponse, а Response остаётся нулевым.
+func (cr *CommandRegistry) Dispatch(req Request) (Response, *ErrorResponse, error) {
+	cr.mu.RLock()
+	cmd, ok := cr.commands[CommandKey{ServiceID: req.ServiceID, Method: req.Method}]
+	if !ok {
+		cmd = cr.fallback
+	}
+	cr.mu.RUnlock()
+
+	var errs []FieldError
+	for _, v := range cmd.Validators {
+		errs = append(errs, v.Validate(req)...)
+	}
+	if len(errs) > 0 {
+		return Response{}, &ErrorResponse{Errors: errs}, nil
+	}
+
+	resp, err := cmd.Executer.Execute(req)
+	if err != nil {
+		return Response{}, nil, err
+	}
+	return resp, nil, nil
+}
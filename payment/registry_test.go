@@ -0,0 +1,78 @@
+package payment
+
+import (
+	"testing"
+	"time"
+)
+
+func fixedNow(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+// TestDispatchUsesRegisteredCommandOverFallback проверяет, что
+// CommandRegistry.Dispatch действительно использует команду, явно
+// зарегистрированную для (service_id, method), а не команду по умолчанию.
+func TestDispatchUsesRegisteredCommandOverFallback(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cr := NewCommandRegistry(Command{
+		Validators: []PaymentValidator{PeriodValidator{Default: 24 * time.Hour}},
+		Executer:   TokenExecuter{Issuer: stubIssuer{}},
+	})
+	cr.Register("billing", "refund", Command{
+		Validators: []PaymentValidator{RefundWindowValidator{Now: fixedNow(now)}},
+		Executer:   RefundExecuter{GenerateRefundID: func() string { return "rfnd_test" }},
+	})
+
+	// Период оплаты уже закончился — зарегистрированная команда refund
+	// должна его пропустить и выдать идентификатор возврата.
+	req := Request{ServiceID: "billing", Method: "refund", From: now.Add(-2 * time.Hour), To: now.Add(-time.Hour)}
+	resp, validationErr, err := cr.Dispatch(req)
+	if err != nil {
+		t.Fatalf("Dispatch вернул ошибку: %v", err)
+	}
+	if validationErr != nil {
+		t.Fatalf("Dispatch вернул ошибку валидации: %+v", validationErr)
+	}
+	if resp.Token != "rfnd_test" {
+		t.Fatalf("ожидался идентификатор возврата rfnd_test, получено %q", resp.Token)
+	}
+
+	// Тот же период, но метод не "refund" — должна сработать команда по
+	// умолчанию (PeriodValidator), которая не возражает против прошедшего
+	// периода.
+	fallbackReq := Request{ServiceID: "billing", Method: "issue", From: now.Add(-2 * time.Hour), To: now.Add(-time.Hour)}
+	if _, validationErr, err := cr.Dispatch(fallbackReq); err != nil || validationErr != nil {
+		t.Fatalf("ожидался успех через fallback-команду, получено validationErr=%+v err=%v", validationErr, err)
+	}
+}
+
+// TestDispatchRejectsRefundForOngoingPeriod проверяет, что зарегистрированный
+// для refund валидатор действительно отличается от команды по умолчанию.
+func TestDispatchRejectsRefundForOngoingPeriod(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cr := NewCommandRegistry(Command{
+		Validators: []PaymentValidator{PeriodValidator{Default: 24 * time.Hour}},
+		Executer:   TokenExecuter{Issuer: stubIssuer{}},
+	})
+	cr.Register("billing", "refund", Command{
+		Validators: []PaymentValidator{RefundWindowValidator{Now: fixedNow(now)}},
+		Executer:   RefundExecuter{GenerateRefundID: func() string { return "rfnd_test" }},
+	})
+
+	req := Request{ServiceID: "billing", Method: "refund", From: now, To: now.Add(time.Hour)}
+	_, validationErr, err := cr.Dispatch(req)
+	if err != nil {
+		t.Fatalf("Dispatch вернул ошибку: %v", err)
+	}
+	if validationErr == nil {
+		t.Fatal("ожидалась ошибка валидации для ещё не закончившегося периода")
+	}
+}
+
+type stubIssuer struct{}
+
+func (stubIssuer) Issue(serviceID, method string, from, to time.Time) (string, error) {
+	return "tok_test", nil
+}
@@ -0,0 +1,22 @@
+// Package payment реализует диспетчер команд оплаты: для каждой пары
+// (service_id, method) можно зарегистрировать свой набор валидаторов и
+// исполнителя, не трогая HTTP-слой.
+package payment
+
+import "time"
+
+// Request представляет запрос на обработку оплаты.
+type Request struct {
+	ServiceID string    `json:"service_id"` // Идентификатор сервиса
+	Method    string    `json:"method"`     // Метод сервиса
+	From      time.Time `json:"from"`       // Начало периода оплаты
+	To        time.Time `json:"to"`         // Конец периода оплаты
+}
+
+// Response представляет ответ после обработки оплаты.
+type Response struct {
+	Token  string    `json:"token"`  // Уникальный токен оплаты
+	From   time.Time `json:"from"`   // Начало периода оплаты
+	To     time.Time `json:"to"`     // Конец периода оплаты
+	Method string    `json:"method"` // Метод сервиса
+}
@@ -0,0 +1,78 @@
+package payment
+
+import "time"
+
+// PaymentValidator проверяет запрос на оплату до того, как он попадёт к
+// исполнителю, и возвращает список ошибок по полям (пустой список — запрос
+// валиден).
+type PaymentValidator interface {
+	Validate(req Request) []FieldError
+}
+
+// ServiceDurationLookup даёт валидатору доступ к максимальной длительности
+// периода оплаты, заявленной сервисом при регистрации.
+type ServiceDurationLookup interface {
+	MaxDurationFor(serviceID, method string) (time.Duration, bool)
+}
+
+// PeriodValidator проверяет, что период оплаты корректен и не превышает
+// максимальную длительность, заданную для метода сервиса в реестре
+// (Lookup), либо Default, если сервис её не заявил.
+type PeriodValidator struct {
+	Lookup  ServiceDurationLookup
+	Default time.Duration
+}
+
+func (v PeriodValidator) Validate(req Request) []FieldError {
+	var errs []FieldError
+
+	if !req.From.Before(req.To) {
+		errs = append(errs, FieldError{
+			Code:    "invalid_period",
+			Message: "начало периода должно быть раньше конца",
+			Field:   "from",
+		})
+		return errs
+	}
+
+	maxDuration := v.Default
+	if v.Lookup != nil {
+		if d, ok := v.Lookup.MaxDurationFor(req.ServiceID, req.Method); ok {
+			maxDuration = d
+		}
+	}
+
+	if maxDuration > 0 && req.To.Sub(req.From) > maxDuration {
+		errs = append(errs, FieldError{
+			Code:    "period_too_long",
+			Message: "период оплаты превышает допустимую длительность",
+			Field:   "to",
+		})
+	}
+
+	return errs
+}
+
+// RefundWindowValidator проверяет, что возврат запрашивается за уже
+// истёкший период оплаты: в отличие от PeriodValidator, используемого для
+// выпуска токенов, возврат не имеет смысла для периода, который ещё не
+// закончился.
+type RefundWindowValidator struct {
+	Now func() time.Time
+}
+
+func (v RefundWindowValidator) Validate(req Request) []FieldError {
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+
+	if req.To.After(now()) {
+		return []FieldError{{
+			Code:    "period_not_elapsed",
+			Message: "возврат доступен только за уже истёкший период оплаты",
+			Field:   "to",
+		}}
+	}
+	return nil
+}
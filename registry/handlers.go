@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// RegisterHandler обрабатывает POST /services.
+func (r *ServiceRegistry) RegisterHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body RegisterRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, "некорректное тело запроса", http.StatusBadRequest)
+		return
+	}
+	defer req.Body.Close()
+
+	inst, err := r.Register(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(inst)
+}
+
+// DeregisterHandler обрабатывает DELETE /services/{id}.
+func (r *ServiceRegistry) DeregisterHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodDelete {
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(req.URL.Path, "/services/")
+	if id == "" {
+		http.Error(w, "не указан id сервиса", http.StatusBadRequest)
+		return
+	}
+
+	if !r.Deregister(id) {
+		http.Error(w, "экземпляр не найден", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DiscoverHandler обрабатывает GET /services?name=...
+func (r *ServiceRegistry) DiscoverHandler(w http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "не указан параметр name", http.StatusBadRequest)
+		return
+	}
+
+	inst, ok := r.Discover(name)
+	if !ok {
+		http.Error(w, "сервис не найден", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inst)
+}
@@ -0,0 +1,266 @@
+// Package registry реализует саморегистрацию сервисов по схеме
+// "hub-and-spoke": каждый сервис регистрируется в реестре, периодически
+// подтверждает свою доступность через heartbeat, а реестр уведомляет
+// зависимые сервисы, когда один из их обязательных сервисов пропадает.
+package registry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MaxMissedHeartbeats — число подряд неудачных проверок, после которого
+// экземпляр сервиса считается мёртвым и удаляется из реестра.
+const MaxMissedHeartbeats = 3
+
+// Instance описывает один зарегистрированный экземпляр сервиса.
+type Instance struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`              // Имя сервиса (например, "service1")
+	Address          string    `json:"address"`           // Базовый адрес для обращения к сервису
+	Methods          []string                  `json:"methods"`                // Методы, которые предоставляет сервис
+	RequiredServices []string                  `json:"required_services"`      // Сервисы, от которых он зависит
+	HeartbeatURL     string                    `json:"heartbeat_url"`          // Куда стучаться за heartbeat
+	MaxDuration      map[string]time.Duration `json:"max_duration,omitempty"` // Макс. длительность периода оплаты по методам
+	LastSeen         time.Time                `json:"last_seen"`
+	missed           int                       // Подряд идущие неудачные heartbeat-проверки
+}
+
+// RegisterRequest — тело запроса POST /services.
+type RegisterRequest struct {
+	Name             string                    `json:"name"`
+	Address          string                    `json:"address"`
+	Methods          []string                  `json:"methods"`
+	RequiredServices []string                  `json:"required_services"`
+	HeartbeatURL     string                    `json:"heartbeat_url"`
+	MaxDuration      map[string]time.Duration `json:"max_duration,omitempty"`
+}
+
+// ServiceRegistry хранит живые экземпляры сервисов и их зависимости.
+type ServiceRegistry struct {
+	mu        sync.RWMutex
+	instances map[string][]*Instance // имя сервиса -> экземпляры
+	byID      map[string]*Instance   // id -> экземпляр
+	client    *http.Client
+}
+
+// New создаёт пустой реестр сервисов.
+func New() *ServiceRegistry {
+	return &ServiceRegistry{
+		instances: make(map[string][]*Instance),
+		byID:      make(map[string]*Instance),
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Register добавляет новый экземпляр сервиса и возвращает его с присвоенным ID.
+func (r *ServiceRegistry) Register(req RegisterRequest) (*Instance, error) {
+	if req.Name == "" || req.Address == "" {
+		return nil, fmt.Errorf("имя и адрес сервиса обязательны")
+	}
+
+	inst := &Instance{
+		ID:               generateID(),
+		Name:             req.Name,
+		Address:          req.Address,
+		Methods:          req.Methods,
+		RequiredServices: req.RequiredServices,
+		HeartbeatURL:     req.HeartbeatURL,
+		MaxDuration:      req.MaxDuration,
+		LastSeen:         time.Now(),
+	}
+
+	r.mu.Lock()
+	wasEmpty := len(r.instances[inst.Name]) == 0
+	r.instances[inst.Name] = append(r.instances[inst.Name], inst)
+	r.byID[inst.ID] = inst
+	r.mu.Unlock()
+
+	if wasEmpty {
+		r.notifySubscribers(inst.Name, "added")
+	}
+
+	return inst, nil
+}
+
+// Deregister удаляет экземпляр сервиса по ID.
+func (r *ServiceRegistry) Deregister(id string) bool {
+	r.mu.Lock()
+	inst, exists := r.byID[id]
+	if !exists {
+		r.mu.Unlock()
+		return false
+	}
+	delete(r.byID, id)
+	r.instances[inst.Name] = removeInstance(r.instances[inst.Name], id)
+	r.mu.Unlock()
+
+	r.notifySubscribers(inst.Name, "removed")
+	return true
+}
+
+// Discover возвращает случайный живой экземпляр сервиса с данным именем
+// (простая балансировка нагрузки случайным выбором). Возвращается
+// снимок экземпляра, а не общий указатель, чтобы вызывающий код (например,
+// сериализация в JSON) не гонялся с heartbeat-проверками за его поля.
+func (r *ServiceRegistry) Discover(name string) (*Instance, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidates := r.instances[name]
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	idx := 0
+	if n, err := rand.Int(rand.Reader, big.NewInt(int64(len(candidates)))); err == nil {
+		idx = int(n.Int64())
+	}
+
+	snapshot := *candidates[idx]
+	return &snapshot, true
+}
+
+// IsServiceAvailable проверяет, что хотя бы один живой экземпляр сервиса
+// поддерживает указанный метод. Используется платёжным обработчиком вместо
+// обращения к статической карте сервисов.
+func (r *ServiceRegistry) IsServiceAvailable(name, method string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, inst := range r.instances[name] {
+		for _, m := range inst.Methods {
+			if m == method {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MaxDurationFor возвращает максимально допустимую длительность периода
+// оплаты для метода сервиса, если она была заявлена при регистрации.
+func (r *ServiceRegistry) MaxDurationFor(name, method string) (time.Duration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, inst := range r.instances[name] {
+		if d, ok := inst.MaxDuration[method]; ok {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// StartHeartbeatChecker запускает фоновую горутину, которая каждые interval
+// опрашивает HeartbeatURL всех экземпляров и удаляет те, что не ответили
+// MaxMissedHeartbeats раз подряд.
+func (r *ServiceRegistry) StartHeartbeatChecker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			r.checkHeartbeats()
+		}
+	}()
+}
+
+func (r *ServiceRegistry) checkHeartbeats() {
+	r.mu.RLock()
+	all := make([]*Instance, 0, len(r.byID))
+	for _, inst := range r.byID {
+		all = append(all, inst)
+	}
+	r.mu.RUnlock()
+
+	for _, inst := range all {
+		if inst.HeartbeatURL == "" {
+			continue
+		}
+		if r.pingHeartbeat(inst) {
+			r.mu.Lock()
+			inst.missed = 0
+			inst.LastSeen = time.Now()
+			r.mu.Unlock()
+			continue
+		}
+
+		r.mu.Lock()
+		inst.missed++
+		dead := inst.missed >= MaxMissedHeartbeats
+		r.mu.Unlock()
+
+		if dead {
+			r.Deregister(inst.ID)
+		}
+	}
+}
+
+func (r *ServiceRegistry) pingHeartbeat(inst *Instance) bool {
+	resp, err := r.client.Get(inst.HeartbeatURL)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// ServicePatch — JSON-патч, который реестр рассылает подписчикам, у которых
+// сервис указан в required_services: "removed" — при удалении любого его
+// экземпляра (даже если другие экземпляры ещё живы), "added" — при
+// регистрации первого экземпляра сервиса.
+type ServicePatch struct {
+	Service string `json:"service"`
+	Op      string `json:"op"` // "removed" | "added"
+}
+
+// notifySubscribers рассылает патч всем живым экземплярам, у которых
+// serviceName указан в required_services.
+func (r *ServiceRegistry) notifySubscribers(serviceName, op string) {
+	r.mu.RLock()
+	subscribers := make([]*Instance, 0)
+	for _, inst := range r.byID {
+		for _, dep := range inst.RequiredServices {
+			if dep == serviceName {
+				subscribers = append(subscribers, inst)
+				break
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	patch := ServicePatch{Service: serviceName, Op: op}
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return
+	}
+
+	for _, sub := range subscribers {
+		go func(address string) {
+			r.client.Post(address+"/services/notify", "application/json", bytes.NewReader(body))
+		}(sub.Address)
+	}
+}
+
+func removeInstance(list []*Instance, id string) []*Instance {
+	out := list[:0]
+	for _, inst := range list {
+		if inst.ID != id {
+			out = append(out, inst)
+		}
+	}
+	return out
+}
+
+func generateID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
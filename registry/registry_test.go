@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestDiscoverReturnsSnapshot проверяет, что Discover не отдаёт наружу
+// общий указатель на Instance: изменение полей оригинала после вызова не
+// должно быть видно в возвращённом значении (иначе конкурентное чтение
+// гонится с checkHeartbeats).
+func TestDiscoverReturnsSnapshot(t *testing.T) {
+	r := New()
+	inst, err := r.Register(RegisterRequest{Name: "svc", Address: "http://svc", Methods: []string{"m1"}})
+	if err != nil {
+		t.Fatalf("Register вернул ошибку: %v", err)
+	}
+
+	snapshot, ok := r.Discover("svc")
+	if !ok {
+		t.Fatal("Discover не нашёл зарегистрированный сервис")
+	}
+	if snapshot == inst {
+		t.Fatal("Discover вернул общий указатель, а не снимок")
+	}
+
+	r.mu.Lock()
+	inst.missed = 7
+	r.mu.Unlock()
+
+	if snapshot.missed == 7 {
+		t.Fatal("снимок отразил изменение оригинального экземпляра")
+	}
+}
+
+// TestHeartbeatRemovalNotifiesSubscribers проверяет, что после трёх подряд
+// неудачных heartbeat-проверок мёртвый экземпляр удаляется из реестра, а
+// подписчики, объявившие его в required_services, получают патч "removed".
+func TestHeartbeatRemovalNotifiesSubscribers(t *testing.T) {
+	r := New()
+
+	var notified int32
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&notified, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer subscriber.Close()
+
+	deadHeartbeat := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer deadHeartbeat.Close()
+
+	if _, err := r.Register(RegisterRequest{
+		Name:         "upstream",
+		Address:      "http://upstream",
+		Methods:      []string{"m1"},
+		HeartbeatURL: deadHeartbeat.URL,
+	}); err != nil {
+		t.Fatalf("Register(upstream) вернул ошибку: %v", err)
+	}
+
+	if _, err := r.Register(RegisterRequest{
+		Name:             "dependent",
+		Address:          subscriber.URL,
+		Methods:          []string{"m1"},
+		RequiredServices: []string{"upstream"},
+	}); err != nil {
+		t.Fatalf("Register(dependent) вернул ошибку: %v", err)
+	}
+
+	for i := 0; i < MaxMissedHeartbeats; i++ {
+		r.checkHeartbeats()
+	}
+
+	if r.IsServiceAvailable("upstream", "m1") {
+		t.Fatal("upstream должен быть удалён после MaxMissedHeartbeats неудач")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&notified) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt32(&notified) == 0 {
+		t.Fatal("подписчик не получил уведомление об удалении upstream")
+	}
+}
@@ -0,0 +1,20 @@
+// Package token выпускает и проверяет подписанные JWT-токены оплаты,
+// поддерживает их отзыв и интроспекцию в стиле RFC 7662.
+package token
+
+import "time"
+
+// Claims — набор claim'ов, зашитых в токен оплаты.
+type Claims struct {
+	ServiceID string    `json:"service_id"`
+	Method    string    `json:"method"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Jti       string    `json:"jti"` // Уникальный идентификатор токена (для отзыва)
+	Exp       int64     `json:"exp"` // Время истечения, unix-секунды
+}
+
+// Expired сообщает, истёк ли токен на момент времени now.
+func (c Claims) Expired(now time.Time) bool {
+	return now.Unix() >= c.Exp
+}
@@ -0,0 +1,45 @@
+package token
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// IntrospectHandler обрабатывает GET /payment/introspect?token=...
+func (i *Issuer) IntrospectHandler(w http.ResponseWriter, r *http.Request) {
+	tok := r.URL.Query().Get("token")
+	if tok == "" {
+		http.Error(w, "не указан параметр token", http.StatusBadRequest)
+		return
+	}
+
+	result := i.Introspect(tok)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// revokeRequest — тело запроса POST /payment/revoke.
+type revokeRequest struct {
+	Token string `json:"token"`
+}
+
+// RevokeHandler обрабатывает POST /payment/revoke.
+func (i *Issuer) RevokeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body revokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "некорректное тело запроса", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := i.Revoke(body.Token); err != nil {
+		http.Error(w, "не удалось отозвать токен", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
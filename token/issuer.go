@@ -0,0 +1,69 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Issuer выпускает и проверяет токены оплаты, объединяя подпись и отзыв.
+type Issuer struct {
+	Signer     Signer
+	Revocation RevocationStore
+}
+
+// NewIssuer создаёт Issuer поверх заданных подписчика и хранилища отзыва.
+func NewIssuer(signer Signer, revocation RevocationStore) *Issuer {
+	return &Issuer{Signer: signer, Revocation: revocation}
+}
+
+// Issue подписывает новый токен на период [from, to]. Токен истекает в
+// момент to.
+func (i *Issuer) Issue(serviceID, method string, from, to time.Time) (string, error) {
+	claims := Claims{
+		ServiceID: serviceID,
+		Method:    method,
+		From:      from,
+		To:        to,
+		Jti:       generateJti(),
+		Exp:       to.Unix(),
+	}
+	return i.Signer.Sign(claims)
+}
+
+// IntrospectionResult — ответ в стиле RFC 7662.
+type IntrospectionResult struct {
+	Active bool   `json:"active"`
+	Claims Claims `json:"claims,omitempty"`
+}
+
+// Introspect проверяет подпись, срок действия и отзыв токена.
+func (i *Issuer) Introspect(tok string) IntrospectionResult {
+	claims, err := i.Signer.Verify(tok)
+	if err != nil {
+		return IntrospectionResult{Active: false}
+	}
+	if claims.Expired(time.Now()) {
+		return IntrospectionResult{Active: false, Claims: claims}
+	}
+	if i.Revocation.IsRevoked(claims.Jti) {
+		return IntrospectionResult{Active: false, Claims: claims}
+	}
+	return IntrospectionResult{Active: true, Claims: claims}
+}
+
+// Revoke проверяет подпись токена и заносит его jti в хранилище отзыва,
+// действуя как центральный kill-switch.
+func (i *Issuer) Revoke(tok string) error {
+	claims, err := i.Signer.Verify(tok)
+	if err != nil {
+		return err
+	}
+	return i.Revocation.Revoke(claims.Jti, time.Unix(claims.Exp, 0))
+}
+
+func generateJti() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,109 @@
+package token
+
+import (
+	"bufio"
+	"os"
+	"sync"
+	"time"
+)
+
+// RevocationStore хранит отозванные jti, чтобы реестр мог действовать как
+// центральный kill-switch, даже если downstream-сервисы проверяют токены
+// локально. Реализации должны самостоятельно вычищать записи с истёкшим
+// сроком годности.
+type RevocationStore interface {
+	Revoke(jti string, exp time.Time) error
+	IsRevoked(jti string) bool
+}
+
+// MemoryRevocationStore — реализация RevocationStore в памяти процесса.
+type MemoryRevocationStore struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time // jti -> время истечения токена
+}
+
+// NewMemoryRevocationStore создаёт пустое хранилище отозванных токенов.
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{revoked: make(map[string]time.Time)}
+}
+
+func (s *MemoryRevocationStore) Revoke(jti string, exp time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[jti] = exp
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsRevoked(jti string) bool {
+	s.mu.RLock()
+	exp, ok := s.revoked[jti]
+	s.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if time.Now().After(exp) {
+		// Токен всё равно больше не валиден по exp — запись можно вычистить.
+		s.mu.Lock()
+		delete(s.revoked, jti)
+		s.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// FileRevocationStore оборачивает MemoryRevocationStore и дублирует каждый
+// отзыв в append-only файл, чтобы список отозванных токенов переживал
+// перезапуск процесса. Это достаточно для небольших инсталляций; для
+// production-нагрузки на это же место можно подставить реализацию на
+// BoltDB, реализующую тот же интерфейс RevocationStore.
+type FileRevocationStore struct {
+	*MemoryRevocationStore
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileRevocationStore загружает ранее отозванные токены из path (если
+// файл существует) и возвращает хранилище, дописывающее новые отзывы в
+// этот же файл.
+func NewFileRevocationStore(path string) (*FileRevocationStore, error) {
+	mem := NewMemoryRevocationStore()
+
+	f, err := os.Open(path)
+	if err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var rec revocationRecord
+			if err := rec.UnmarshalLine(scanner.Text()); err == nil {
+				mem.revoked[rec.Jti] = rec.Exp
+			}
+		}
+		f.Close()
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return &FileRevocationStore{MemoryRevocationStore: mem, path: path}, nil
+}
+
+func (s *FileRevocationStore) Revoke(jti string, exp time.Time) error {
+	if err := s.MemoryRevocationStore.Revoke(jti, exp); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rec := revocationRecord{Jti: jti, Exp: exp}
+	line, err := rec.MarshalLine()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(line + "\n")
+	return err
+}
@@ -0,0 +1,24 @@
+package token
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// revocationRecord — одна строка в файле-журнале FileRevocationStore.
+type revocationRecord struct {
+	Jti string    `json:"jti"`
+	Exp time.Time `json:"exp"`
+}
+
+func (r revocationRecord) MarshalLine() (string, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (r *revocationRecord) UnmarshalLine(line string) error {
+	return json.Unmarshal([]byte(line), r)
+}
@@ -0,0 +1,58 @@
+package token
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryRevocationStoreExpiry(t *testing.T) {
+	store := NewMemoryRevocationStore()
+
+	if store.IsRevoked("jti-1") {
+		t.Fatal("неотозванный jti не должен считаться отозванным")
+	}
+
+	store.Revoke("jti-1", time.Now().Add(-time.Second)) // уже истёкший токен
+	if store.IsRevoked("jti-1") {
+		t.Fatal("запись с истёкшим exp не должна считаться активно отозванной")
+	}
+
+	store.Revoke("jti-2", time.Now().Add(time.Hour))
+	if !store.IsRevoked("jti-2") {
+		t.Fatal("jti-2 должен считаться отозванным до истечения exp")
+	}
+}
+
+func TestIssuerIntrospectHonorsRevocationAndExpiry(t *testing.T) {
+	signer, err := NewSigner("HS256", []byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewSigner вернул ошибку: %v", err)
+	}
+	issuer := NewIssuer(signer, NewMemoryRevocationStore())
+
+	now := time.Now()
+	tok, err := issuer.Issue("billing", "refund", now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Issue вернул ошибку: %v", err)
+	}
+
+	result := issuer.Introspect(tok)
+	if !result.Active {
+		t.Fatal("свежевыпущенный токен должен быть активен")
+	}
+
+	if err := issuer.Revoke(tok); err != nil {
+		t.Fatalf("Revoke вернул ошибку: %v", err)
+	}
+	if issuer.Introspect(tok).Active {
+		t.Fatal("отозванный токен не должен считаться активным")
+	}
+
+	expiredTok, err := issuer.Issue("billing", "refund", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Issue вернул ошибку: %v", err)
+	}
+	if issuer.Introspect(expiredTok).Active {
+		t.Fatal("истёкший токен не должен считаться активным")
+	}
+}
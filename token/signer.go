@@ -0,0 +1,196 @@
+package token
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Signer подписывает и проверяет JWT, несущие Claims.
+type Signer interface {
+	Sign(claims Claims) (string, error)
+	Verify(token string) (Claims, error)
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// NewSigner создаёт подписчика для алгоритма alg ("HS256" или "RS256").
+// Для HS256 key — это общий секрет, для RS256 — PEM-блок приватного ключа
+// (PKCS#1 или PKCS#8).
+func NewSigner(alg string, key []byte) (Signer, error) {
+	switch strings.ToUpper(alg) {
+	case "HS256":
+		return &hmacSigner{secret: key}, nil
+	case "RS256":
+		priv, err := parseRSAPrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось разобрать RSA-ключ: %w", err)
+		}
+		return &rsaSigner{private: priv, public: &priv.PublicKey}, nil
+	default:
+		return nil, fmt.Errorf("неподдерживаемый алгоритм подписи: %s", alg)
+	}
+}
+
+func encodeSegment(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func signingInput(header jwtHeader, claims Claims) (string, error) {
+	h, err := encodeSegment(header)
+	if err != nil {
+		return "", err
+	}
+	c, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+	return h + "." + c, nil
+}
+
+func splitToken(tok string) (jwtHeader, Claims, string, error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 {
+		return jwtHeader{}, Claims{}, "", errors.New("некорректный формат токена")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return jwtHeader{}, Claims{}, "", err
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return jwtHeader{}, Claims{}, "", err
+	}
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return jwtHeader{}, Claims{}, "", err
+	}
+	var claims Claims
+	if err := json.Unmarshal(claimsBytes, &claims); err != nil {
+		return jwtHeader{}, Claims{}, "", err
+	}
+
+	return header, claims, parts[2], nil
+}
+
+// hmacSigner реализует HS256.
+type hmacSigner struct {
+	secret []byte
+}
+
+func (s *hmacSigner) Sign(claims Claims) (string, error) {
+	header := jwtHeader{Alg: "HS256", Typ: "JWT"}
+	input, err := signingInput(header, claims)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(input))
+	sig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	return input + "." + sig, nil
+}
+
+func (s *hmacSigner) Verify(tok string) (Claims, error) {
+	header, claims, sigPart, err := splitToken(tok)
+	if err != nil {
+		return Claims{}, err
+	}
+	if header.Alg != "HS256" {
+		return Claims{}, fmt.Errorf("неожиданный алгоритм подписи: %s", header.Alg)
+	}
+
+	input := strings.TrimSuffix(tok, "."+sigPart)
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(input))
+	expected := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(sigPart)) {
+		return Claims{}, errors.New("неверная подпись токена")
+	}
+	return claims, nil
+}
+
+// rsaSigner реализует RS256.
+type rsaSigner struct {
+	private *rsa.PrivateKey
+	public  *rsa.PublicKey
+}
+
+func (s *rsaSigner) Sign(claims Claims) (string, error) {
+	header := jwtHeader{Alg: "RS256", Typ: "JWT"}
+	input, err := signingInput(header, claims)
+	if err != nil {
+		return "", err
+	}
+
+	hashed := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.private, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func (s *rsaSigner) Verify(tok string) (Claims, error) {
+	header, claims, sigPart, err := splitToken(tok)
+	if err != nil {
+		return Claims{}, err
+	}
+	if header.Alg != "RS256" {
+		return Claims{}, fmt.Errorf("неожиданный алгоритм подписи: %s", header.Alg)
+	}
+
+	input := strings.TrimSuffix(tok, "."+sigPart)
+	sig, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	hashed := sha256.Sum256([]byte(input))
+	if err := rsa.VerifyPKCS1v15(s.public, crypto.SHA256, hashed[:], sig); err != nil {
+		return Claims{}, errors.New("неверная подпись токена")
+	}
+	return claims, nil
+}
+
+func parseRSAPrivateKey(key []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("не найден PEM-блок")
+	}
+
+	if priv, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return priv, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("ключ не является RSA-ключом")
+	}
+	return priv, nil
+}
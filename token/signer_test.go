@@ -0,0 +1,74 @@
+package token
+
+import (
+	"testing"
+	"time"
+)
+
+func testClaims() Claims {
+	return Claims{
+		ServiceID: "billing",
+		Method:    "refund",
+		From:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		Jti:       "test-jti",
+		Exp:       time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC).Unix(),
+	}
+}
+
+func TestHMACSignerRoundTrip(t *testing.T) {
+	signer, err := NewSigner("HS256", []byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewSigner вернул ошибку: %v", err)
+	}
+
+	claims := testClaims()
+	tok, err := signer.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign вернул ошибку: %v", err)
+	}
+
+	got, err := signer.Verify(tok)
+	if err != nil {
+		t.Fatalf("Verify вернул ошибку: %v", err)
+	}
+	if got.Jti != claims.Jti || got.ServiceID != claims.ServiceID {
+		t.Fatalf("claims после round-trip не совпадают: %+v != %+v", got, claims)
+	}
+}
+
+func TestHMACSignerRejectsTamperedToken(t *testing.T) {
+	signer, err := NewSigner("HS256", []byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewSigner вернул ошибку: %v", err)
+	}
+
+	tok, err := signer.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign вернул ошибку: %v", err)
+	}
+
+	tampered := tok[:len(tok)-1] + "x"
+	if _, err := signer.Verify(tampered); err == nil {
+		t.Fatal("ожидалась ошибка проверки подписи для изменённого токена")
+	}
+}
+
+func TestHMACSignerRejectsForeignSecret(t *testing.T) {
+	signer, err := NewSigner("HS256", []byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewSigner вернул ошибку: %v", err)
+	}
+	other, err := NewSigner("HS256", []byte("другой-секрет"))
+	if err != nil {
+		t.Fatalf("NewSigner вернул ошибку: %v", err)
+	}
+
+	tok, err := signer.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign вернул ошибку: %v", err)
+	}
+	if _, err := other.Verify(tok); err == nil {
+		t.Fatal("ожидалась ошибка проверки токена, подписанного другим секретом")
+	}
+}